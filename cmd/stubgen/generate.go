@@ -0,0 +1,271 @@
+package main
+
+import (
+	"fmt"
+	"go/types"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+)
+
+// Config describes the single type stubgen is asked to scaffold.
+type Config struct {
+	Dir        string
+	TypeName   string
+	NamePrefix string
+}
+
+// Model is the data handed to the code templates.
+type Model struct {
+	PackageName       string
+	StubbedStructName string
+	WrapperName       string
+	Methods           []MethodModel
+}
+
+// MethodModel describes one method of the stubbed type, already flattened
+// across any embedded interfaces/structs.
+type MethodModel struct {
+	Name     string
+	Params   []Param
+	Results  []Result
+	Variadic bool
+}
+
+// Param is one argument of a method, with a generated, collision-free name.
+type Param struct {
+	Name string
+	Type string
+}
+
+// Result is one return value of a method.
+type Result struct {
+	Type string
+	// Nilable reports whether Type's zero value is the nil literal
+	// (pointer, slice, map, chan, func, interface). Value types - structs,
+	// arrays, and basic kinds - need their own zero-value expression
+	// instead; see zeroValue.
+	Nilable bool
+}
+
+// LoadModel loads the package at cfg.Dir, finds cfg.TypeName, flattens its
+// method set (including methods promoted from embedded interfaces), and
+// returns the data needed to render the stub.
+func LoadModel(cfg *Config) (*Model, error) {
+	pkgs, err := packages.Load(&packages.Config{
+		Mode: packages.NeedName | packages.NeedTypes | packages.NeedTypesInfo | packages.NeedSyntax | packages.NeedImports,
+		Dir:  cfg.Dir,
+	}, ".")
+	if err != nil {
+		return nil, fmt.Errorf("loading package at %s: %w", cfg.Dir, err)
+	}
+	if len(pkgs) == 0 || len(pkgs[0].Errors) > 0 {
+		return nil, fmt.Errorf("no loadable package at %s", cfg.Dir)
+	}
+	pkg := pkgs[0]
+
+	obj := pkg.Types.Scope().Lookup(cfg.TypeName)
+	if obj == nil {
+		return nil, fmt.Errorf("type %s not found in package %s", cfg.TypeName, pkg.Name)
+	}
+	named, ok := obj.Type().(*types.Named)
+	if !ok {
+		return nil, fmt.Errorf("%s is not a named type", cfg.TypeName)
+	}
+
+	methodSet := flattenMethodSet(named)
+
+	qualifier := func(p *types.Package) string {
+		if p == nil || p.Path() == pkg.PkgPath {
+			return ""
+		}
+		return p.Name()
+	}
+
+	methods := make([]MethodModel, 0, len(methodSet))
+	for _, fn := range methodSet {
+		sig := fn.Type().(*types.Signature)
+		mm := MethodModel{
+			Name:     fn.Name(),
+			Variadic: sig.Variadic(),
+		}
+		rawNames := make([]string, sig.Params().Len())
+		for i := 0; i < sig.Params().Len(); i++ {
+			v := sig.Params().At(i)
+			name := v.Name()
+			if name == "" || name == "_" {
+				name = paramNameFromType(v.Type(), sig.Variadic() && i == sig.Params().Len()-1)
+			}
+			rawNames[i] = name
+		}
+		names := disambiguateAll(rawNames)
+		for i := 0; i < sig.Params().Len(); i++ {
+			mm.Params = append(mm.Params, Param{
+				Name: names[i],
+				Type: types.TypeString(sig.Params().At(i).Type(), qualifier),
+			})
+		}
+		for i := 0; i < sig.Results().Len(); i++ {
+			resultType := sig.Results().At(i).Type()
+			mm.Results = append(mm.Results, Result{
+				Type:    types.TypeString(resultType, qualifier),
+				Nilable: isNilable(resultType),
+			})
+		}
+		methods = append(methods, mm)
+	}
+
+	return &Model{
+		PackageName:       pkg.Name,
+		StubbedStructName: cfg.NamePrefix + "bed" + cfg.TypeName,
+		WrapperName:       cfg.NamePrefix + cfg.TypeName,
+		Methods:           methods,
+	}, nil
+}
+
+// flattenMethodSet walks the promotion rules used by the Go SSA package's
+// promote-wrapper logic: embedded interfaces/structs contribute their
+// methods unless an outer (or earlier-embedded) method already claims the
+// name, in which case the outer method wins.
+func flattenMethodSet(named *types.Named) []*types.Func {
+	byName := make(map[string]*types.Func)
+
+	var walk func(t types.Type, depth int)
+	walk = func(t types.Type, depth int) {
+		if p, ok := t.(*types.Pointer); ok {
+			t = p.Elem()
+		}
+		// an embedded named (non-interface) type contributes its own
+		// declared methods; for an interface, NumMethods is 0 and its
+		// methods are collected below.
+		if n, ok := t.(*types.Named); ok && depth > 0 {
+			for i := 0; i < n.NumMethods(); i++ {
+				m := n.Method(i)
+				if _, claimed := byName[m.Name()]; !claimed {
+					byName[m.Name()] = m
+				}
+			}
+		}
+		switch u := t.Underlying().(type) {
+		case *types.Interface:
+			for i := 0; i < u.NumExplicitMethods(); i++ {
+				m := u.ExplicitMethod(i)
+				if _, claimed := byName[m.Name()]; !claimed {
+					byName[m.Name()] = m
+				}
+			}
+			for i := 0; i < u.NumEmbeddeds(); i++ {
+				walk(u.EmbeddedType(i), depth+1)
+			}
+		case *types.Struct:
+			// direct methods of the top-level named type are added by the
+			// caller, and those of an embedded named type above; here we
+			// only need to recurse into embedded fields.
+			for i := 0; i < u.NumFields(); i++ {
+				f := u.Field(i)
+				if !f.Embedded() {
+					continue
+				}
+				walk(f.Type(), depth+1)
+			}
+		}
+	}
+
+	if iface, ok := named.Underlying().(*types.Interface); ok {
+		walk(iface, 0)
+	} else {
+		for i := 0; i < named.NumMethods(); i++ {
+			m := named.Method(i)
+			byName[m.Name()] = m
+		}
+		walk(named.Underlying(), 0)
+	}
+
+	out := make([]*types.Func, 0, len(byName))
+	for _, m := range byName {
+		out = append(out, m)
+	}
+	return out
+}
+
+// paramNameFromType derives a readable argument name from its type the way
+// moq/mockgen do: "chan int" -> "intCh", "[]MyType" -> "myTypes", a trailing
+// variadic "...T" is named as its slice form.
+func paramNameFromType(t types.Type, variadic bool) string {
+	switch u := t.(type) {
+	case *types.Chan:
+		return paramNameFromType(u.Elem(), false) + "Ch"
+	case *types.Pointer:
+		return paramNameFromType(u.Elem(), false)
+	case *types.Slice:
+		return pluralize(paramNameFromType(u.Elem(), false))
+	case *types.Named:
+		return lowerFirst(u.Obj().Name())
+	case *types.Basic:
+		return u.Name()
+	default:
+		return "arg"
+	}
+}
+
+// isNilable reports whether t's zero value is the nil literal. A named
+// type's zero value follows its underlying type - e.g. a named struct
+// type is not nilable even though an interface it satisfies is.
+func isNilable(t types.Type) bool {
+	switch t.Underlying().(type) {
+	case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature, *types.Interface:
+		return true
+	default:
+		return false
+	}
+}
+
+func pluralize(s string) string {
+	if strings.HasSuffix(s, "s") {
+		return s
+	}
+	return s + "s"
+}
+
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToLower(s[:1]) + s[1:]
+}
+
+// disambiguateAll assigns a collision-free name to each entry of names,
+// preserving order. A name that only occurs once is left untouched; a name
+// that occurs more than once gets every occurrence (including the first)
+// suffixed with its 1-based occurrence count, e.g. two "string" params
+// become "string1"/"string2". "s" is reserved for the receiver, so it's
+// treated as already taken even if no param happens to collide with it.
+func disambiguateAll(names []string) []string {
+	counts := map[string]int{"s": 1}
+	for _, name := range names {
+		counts[name]++
+	}
+
+	seen := make(map[string]int, len(names))
+	out := make([]string, len(names))
+	for i, name := range names {
+		if counts[name] <= 1 {
+			out[i] = name
+			continue
+		}
+		seen[name]++
+		out[i] = fmt.Sprintf("%s%d", name, seen[name])
+	}
+	return out
+}
+
+func toSnakeCase(s string) string {
+	var b strings.Builder
+	for i, r := range s {
+		if i > 0 && r >= 'A' && r <= 'Z' {
+			b.WriteByte('_')
+		}
+		b.WriteRune(r)
+	}
+	return strings.ToLower(b.String())
+}
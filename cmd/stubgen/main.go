@@ -0,0 +1,61 @@
+// Command stubgen generates the StubWrapper -> Mocked -> StubbedStruct
+// hierarchy described in the doc comment on stub.Mocked.
+//
+// Usage:
+//
+//	//go:generate go run github.com/CameronHonis/stub/cmd/stubgen -type=Repo -out=repo_stub.go
+//
+// Given the name of an interface or concrete type declared in the package
+// being generated for, stubgen emits:
+//
+//  1. a StubbedStruct with one no-op method per method in the method set
+//     of -type, matching its signature exactly;
+//  2. a StubWrapper that embeds *stub.Mocked[StubbedStruct];
+//  3. for each method, a wrapper body of the form
+//     `rets := s.Call("Method", arg0, arg1)` followed by
+//     `return stub.RetOrZero[RetType](rets[0]), ...`,
+//     with variadic args splatted into separate Call args and zero-value
+//     fallbacks for untyped nils.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	typeName := flag.String("type", "", "name of the interface or struct to stub (required)")
+	outPath := flag.String("out", "", "output file path (defaults to <snake_case type>_stub.go)")
+	dir := flag.String("dir", ".", "package directory to load")
+	pkgPrefix := flag.String("prefix", "Stub", "prefix used for the generated StubWrapper/StubbedStruct names")
+	flag.Parse()
+
+	if *typeName == "" {
+		fmt.Fprintln(os.Stderr, "stubgen: -type is required")
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	cfg := &Config{
+		Dir:        *dir,
+		TypeName:   *typeName,
+		NamePrefix: *pkgPrefix,
+	}
+
+	model, err := LoadModel(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "stubgen: %s\n", err)
+		os.Exit(1)
+	}
+
+	out := *outPath
+	if out == "" {
+		out = fmt.Sprintf("%s_stub.go", toSnakeCase(*typeName))
+	}
+
+	if err := Render(out, model); err != nil {
+		fmt.Fprintf(os.Stderr, "stubgen: %s\n", err)
+		os.Exit(1)
+	}
+}
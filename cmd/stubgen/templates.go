@@ -0,0 +1,161 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"golang.org/x/tools/imports"
+)
+
+const fileTmpl = `// Code generated by stubgen. DO NOT EDIT.
+
+package {{.PackageName}}
+
+import "github.com/CameronHonis/stub"
+
+type {{.StubbedStructName}} struct{}
+
+{{range .Methods}}
+func (s *{{$.StubbedStructName}}) {{.Name}}({{paramList .Params .Variadic}}) {{resultList .Results}} {
+	return {{zeroList .Results}}
+}
+{{end}}
+
+type {{.WrapperName}} struct {
+	*stub.Mocked[{{.StubbedStructName}}]
+}
+
+func New{{.WrapperName}}() *{{.WrapperName}} {
+	w := &{{.WrapperName}}{}
+	w.Mocked = stub.NewMocked[{{.StubbedStructName}}](w, &{{.StubbedStructName}}{})
+	return w
+}
+
+{{range .Methods}}
+func (s *{{$.WrapperName}}) {{.Name}}({{paramList .Params .Variadic}}) {{resultList .Results}} {
+{{if .Variadic}}	callArgs := []interface{}{ {{nonVariadicArgs .Params}} }
+	for _, v := range {{lastParamName .Params}} {
+		callArgs = append(callArgs, v)
+	}
+	rets := s.Call("{{.Name}}", callArgs...)
+{{else}}	rets := s.Call("{{.Name}}"{{range .Params}}, {{.Name}}{{end}})
+{{end}}{{if .Results}}	return {{resultAssertions .Results}}
+{{else}}	_ = rets
+{{end}}}
+{{end}}
+`
+
+var funcMap = template.FuncMap{
+	"paramList":        paramList,
+	"resultList":       resultList,
+	"zeroList":         zeroList,
+	"resultAssertions": resultAssertions,
+	"nonVariadicArgs":  nonVariadicArgs,
+	"lastParamName":    lastParamName,
+}
+
+// nonVariadicArgs renders every param up to (excluding) the trailing
+// variadic one, as Call args - the variadic param itself is splatted
+// separately so each element is recorded as its own Call arg instead of
+// a single slice value.
+func nonVariadicArgs(params []Param) string {
+	names := make([]string, len(params)-1)
+	for i := 0; i < len(params)-1; i++ {
+		names[i] = params[i].Name
+	}
+	return strings.Join(names, ", ")
+}
+
+func lastParamName(params []Param) string {
+	return params[len(params)-1].Name
+}
+
+func paramList(params []Param, variadic bool) string {
+	parts := make([]string, len(params))
+	for i, p := range params {
+		typ := p.Type
+		if variadic && i == len(params)-1 {
+			typ = "..." + strings.TrimPrefix(typ, "[]")
+		}
+		parts[i] = fmt.Sprintf("%s %s", p.Name, typ)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func resultList(results []Result) string {
+	if len(results) == 0 {
+		return ""
+	}
+	if len(results) == 1 {
+		return results[0].Type
+	}
+	parts := make([]string, len(results))
+	for i, r := range results {
+		parts[i] = r.Type
+	}
+	return "(" + strings.Join(parts, ", ") + ")"
+}
+
+func zeroList(results []Result) string {
+	zeros := make([]string, len(results))
+	for i, r := range results {
+		zeros[i] = zeroValue(r)
+	}
+	return strings.Join(zeros, ", ")
+}
+
+// zeroValue returns a valid zero-value literal for r. Pointers, slices,
+// maps, chans, funcs, and interfaces (r.Nilable) zero to nil; everything
+// else - named basic types, structs, arrays, and the predeclared basic
+// kinds alike - gets *new(T), which is always a valid zero value
+// regardless of what T turns out to be. This deliberately avoids
+// string-matching r.Type: a named type like "Status" (underlying int)
+// looks like a struct syntactically but "Status{}" doesn't compile, and
+// neither does "byte{}" or "complex128{}".
+func zeroValue(r Result) string {
+	if r.Nilable {
+		return "nil"
+	}
+	return fmt.Sprintf("*new(%s)", r.Type)
+}
+
+// resultAssertions renders `stub.RetOrZero[RetType](rets[0]), stub.RetOrZero[RetType2](rets[1]), ...`
+// over the already-evaluated `rets` slice; RetOrZero gives a zero-value
+// fallback for a nil return (a plain type assertion on a nil interface
+// panics rather than yielding the zero value).
+func resultAssertions(results []Result) string {
+	parts := make([]string, len(results))
+	for i, r := range results {
+		parts[i] = fmt.Sprintf("stub.RetOrZero[%s](rets[%d])", r.Type, i)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// Render executes the template against model and writes gofmt'd output to outPath.
+func Render(outPath string, model *Model) error {
+	tmpl, err := template.New("stub").Funcs(funcMap).Parse(fileTmpl)
+	if err != nil {
+		return fmt.Errorf("parsing template: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, model); err != nil {
+		return fmt.Errorf("executing template: %w", err)
+	}
+
+	// imports.Process gofmt's the output and, unlike go/format.Source,
+	// also resolves and adds the import block for types referenced in
+	// method signatures (e.g. time.Time) that aren't the stub package
+	// itself.
+	formatted, err := imports.Process(outPath, buf.Bytes(), nil)
+	if err != nil {
+		// write the unformatted source so the user can see what went wrong
+		_ = os.WriteFile(outPath, buf.Bytes(), 0o644)
+		return fmt.Errorf("goimports on generated output: %w", err)
+	}
+
+	return os.WriteFile(outPath, formatted, 0o644)
+}
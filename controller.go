@@ -0,0 +1,162 @@
+package stub
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/CameronHonis/stub/match"
+)
+
+//	TestingT is the subset of *testing.T a Controller needs. It's declared
+//	here (rather than importing "testing") so non-test callers, and tests
+//	using a fake, can supply their own.
+type TestingT interface {
+	Errorf(format string, args ...interface{})
+	Cleanup(fn func())
+}
+
+//	Controller tracks expected calls across one or more Mocked instances,
+//	modeled after gomock's Controller. It's a structured alternative to the
+//	free-form stub-and-inspect model the rest of this package offers: call
+//	Expect for every call you expect to happen, then Finish to assert that
+//	they did.
+type Controller struct {
+	t                  TestingT
+	mu                 sync.Mutex
+	expectationsByMock map[CallHookable][]*Expectation
+}
+
+//	NewController returns a Controller and registers a t.Cleanup that
+//	calls Finish, so tests don't need to call it explicitly.
+func NewController(t TestingT) *Controller {
+	c := &Controller{
+		t:                  t,
+		expectationsByMock: make(map[CallHookable][]*Expectation),
+	}
+	t.Cleanup(c.Finish)
+	return c
+}
+
+//	Expectation configures one expected call, built up fluently via
+//	Times/InOrder/Return. It's returned by Controller.Expect.
+type Expectation struct {
+	methodName string
+	matchers   []match.Matcher
+	minTimes   int
+	maxTimes   int
+	calls      int
+	after      *Expectation
+	rets       []interface{}
+}
+
+//	Times sets the exact number of times this call is expected. The
+//	default, absent a call to Times, is exactly once.
+func (e *Expectation) Times(n int) *Expectation {
+	e.minTimes = n
+	e.maxTimes = n
+	return e
+}
+
+//	InOrder declares that this expectation may not be satisfied until prev
+//	has reached its own minimum call count.
+func (e *Expectation) InOrder(prev *Expectation) *Expectation {
+	e.after = prev
+	return e
+}
+
+//	Return sets the values this call returns once matched.
+func (e *Expectation) Return(rets ...interface{}) *Expectation {
+	e.rets = rets
+	return e
+}
+
+func (e *Expectation) saturated() bool {
+	return e.calls >= e.maxTimes
+}
+
+func (e *Expectation) ready() bool {
+	return e.after == nil || e.after.calls >= e.after.minTimes
+}
+
+func (e *Expectation) matches(args []interface{}) bool {
+	if len(e.matchers) != len(args) {
+		return false
+	}
+	for i, m := range e.matchers {
+		if !m.Matches(args[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+//	Expect registers an expected call to methodName on mock, matched
+//	against matchers positionally. The returned *Expectation is configured
+//	further via Times/InOrder/Return.
+func (c *Controller) Expect(mock CallHookable, methodName string, matchers ...match.Matcher) *Expectation {
+	c.ensureHookInstalled(mock)
+
+	e := &Expectation{
+		methodName: methodName,
+		matchers:   matchers,
+		minTimes:   1,
+		maxTimes:   1,
+	}
+
+	c.mu.Lock()
+	c.expectationsByMock[mock] = append(c.expectationsByMock[mock], e)
+	c.mu.Unlock()
+
+	return e
+}
+
+//	Finish reports (via t.Errorf) any expectation that never reached its
+//	minimum call count. It's safe to call more than once; NewController
+//	already arranges for it to run via t.Cleanup.
+func (c *Controller) Finish() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, expectations := range c.expectationsByMock {
+		for _, e := range expectations {
+			if e.calls < e.minTimes {
+				c.t.Errorf("expected %s to be called %d time(s), matching %v, but it was called %d time(s)",
+					e.methodName, e.minTimes, e.matchers, e.calls)
+			}
+		}
+	}
+}
+
+//	ensureHookInstalled installs this Controller's dispatch hook on mock
+//	the first time it's used in an Expect call.
+func (c *Controller) ensureHookInstalled(mock CallHookable) {
+	c.mu.Lock()
+	_, installed := c.expectationsByMock[mock]
+	c.mu.Unlock()
+	if installed {
+		return
+	}
+	mock.SetCallHook(func(methodName string, args []interface{}) ([]interface{}, bool) {
+		return c.dispatch(mock, methodName, args)
+	})
+}
+
+//	dispatch finds the first unsaturated, ready expectation on mock whose
+//	matchers match args, records the call against it, and returns its
+//	configured rets. If nothing matches, it fails the test via t.Errorf and
+//	reports the call as unhandled so Call falls through to its normal
+//	(non-Controller) behavior.
+func (c *Controller) dispatch(mock CallHookable, methodName string, args []interface{}) ([]interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, e := range c.expectationsByMock[mock] {
+		if e.methodName != methodName || e.saturated() || !e.ready() || !e.matches(args) {
+			continue
+		}
+		e.calls++
+		return e.rets, true
+	}
+
+	c.t.Errorf("unexpected call to %s with args %s", methodName, fmt.Sprint(args))
+	return nil, false
+}
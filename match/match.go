@@ -0,0 +1,221 @@
+//	Package match provides argument matchers for use with
+//	Mocked.WasMethodCalledMatching and Mocked.CallsMatching. A Matcher
+//	stands in for an exact argument value when reflect.DeepEqual is too
+//	strict to express the assertion you actually want to make, e.g. "any
+//	string" or "a pointer whose target equals Y".
+package match
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+)
+
+//	Matcher is satisfied by anything that can decide whether an argument
+//	matches. A Mocked compares each positional arg against a Matcher (if
+//	that's what was passed in place of an exact value) via Matches instead
+//	of reflect.DeepEqual. String() describes the matcher; Controller uses
+//	it when reporting an expectation that was not met.
+type Matcher interface {
+	Matches(x interface{}) bool
+	String() string
+}
+
+type matcherFunc struct {
+	matches func(x interface{}) bool
+	str     string
+}
+
+func (m *matcherFunc) Matches(x interface{}) bool { return m.matches(x) }
+func (m *matcherFunc) String() string             { return m.str }
+
+//	Any matches any value, including nil.
+func Any() Matcher {
+	return &matcherFunc{
+		matches: func(x interface{}) bool { return true },
+		str:     "is anything",
+	}
+}
+
+//	Eq matches a value equal to v, per reflect.DeepEqual. This is the
+//	matcher an exact (non-Matcher) argument is implicitly treated as.
+func Eq(v interface{}) Matcher {
+	return &matcherFunc{
+		matches: func(x interface{}) bool { return reflect.DeepEqual(x, v) },
+		str:     fmt.Sprintf("is equal to %v", v),
+	}
+}
+
+//	Nil matches a nil value, including a typed nil (e.g. a nil *Foo or a
+//	nil slice/map/chan/func held in the interface{}).
+func Nil() Matcher {
+	return &matcherFunc{
+		matches: isNil,
+		str:     "is nil",
+	}
+}
+
+//	NotNil matches any non-nil value.
+func NotNil() Matcher {
+	return &matcherFunc{
+		matches: func(x interface{}) bool { return !isNil(x) },
+		str:     "is not nil",
+	}
+}
+
+func isNil(x interface{}) bool {
+	if x == nil {
+		return true
+	}
+	v := reflect.ValueOf(x)
+	switch v.Kind() {
+	case reflect.Chan, reflect.Func, reflect.Map, reflect.Ptr, reflect.Slice, reflect.Interface:
+		return v.IsNil()
+	default:
+		return false
+	}
+}
+
+//	AssignableToTypeOf matches any value whose type is assignable to the
+//	type of v. v may also be a reflect.Type, which is used as is; that is
+//	how to match any implementer of an interface, e.g.
+//	AssignableToTypeOf(reflect.TypeOf((*myInterface)(nil)).Elem()), since
+//	a nil interface value carries no type of its own.
+func AssignableToTypeOf(v interface{}) Matcher {
+	t, isType := v.(reflect.Type)
+	if !isType {
+		t = reflect.TypeOf(v)
+	}
+	return &matcherFunc{
+		matches: func(x interface{}) bool {
+			if x == nil {
+				return false
+			}
+			return reflect.TypeOf(x).AssignableTo(t)
+		},
+		str: fmt.Sprintf("is assignable to %s", t),
+	}
+}
+
+//	Regex matches a string (or fmt.Stringer) argument against pattern.
+func Regex(pattern string) Matcher {
+	re := regexp.MustCompile(pattern)
+	return &matcherFunc{
+		matches: func(x interface{}) bool {
+			s, ok := x.(string)
+			if !ok {
+				if stringer, ok := x.(fmt.Stringer); ok {
+					s = stringer.String()
+				} else {
+					return false
+				}
+			}
+			return re.MatchString(s)
+		},
+		str: fmt.Sprintf("matches regex %q", pattern),
+	}
+}
+
+//	Len matches any value with a Len() of n: an array, slice, map, chan,
+//	or string.
+func Len(n int) Matcher {
+	return &matcherFunc{
+		matches: func(x interface{}) bool {
+			if x == nil {
+				return false
+			}
+			v := reflect.ValueOf(x)
+			switch v.Kind() {
+			case reflect.Array, reflect.Slice, reflect.Map, reflect.Chan, reflect.String:
+				return v.Len() == n
+			default:
+				return false
+			}
+		},
+		str: fmt.Sprintf("has length %d", n),
+	}
+}
+
+//	InRange matches any ordered numeric value x with lo <= x <= hi
+//	(inclusive). lo, hi and the matched argument must all have the same
+//	type; a value of any other type does not match.
+func InRange(lo, hi interface{}) Matcher {
+	loVal := reflect.ValueOf(lo)
+	hiVal := reflect.ValueOf(hi)
+	return &matcherFunc{
+		matches: func(x interface{}) bool {
+			if x == nil {
+				return false
+			}
+			xVal := reflect.ValueOf(x)
+			if xVal.Type() != loVal.Type() || xVal.Type() != hiVal.Type() {
+				return false
+			}
+			switch xVal.Kind() {
+			case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+				return xVal.Int() >= loVal.Int() && xVal.Int() <= hiVal.Int()
+			case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+				return xVal.Uint() >= loVal.Uint() && xVal.Uint() <= hiVal.Uint()
+			case reflect.Float32, reflect.Float64:
+				return xVal.Float() >= loVal.Float() && xVal.Float() <= hiVal.Float()
+			default:
+				return false
+			}
+		},
+		str: fmt.Sprintf("is in range [%v, %v]", lo, hi),
+	}
+}
+
+//	Func matches any value for which fn returns true.
+func Func(fn func(x interface{}) bool) Matcher {
+	return &matcherFunc{
+		matches: fn,
+		str:     "satisfies custom func",
+	}
+}
+
+//	AllOf matches a value that matches every one of matchers.
+func AllOf(matchers ...Matcher) Matcher {
+	return &matcherFunc{
+		matches: func(x interface{}) bool {
+			for _, m := range matchers {
+				if !m.Matches(x) {
+					return false
+				}
+			}
+			return true
+		},
+		str: joinMatchers("all of", matchers),
+	}
+}
+
+//	AnyOf matches a value that matches at least one of matchers.
+func AnyOf(matchers ...Matcher) Matcher {
+	return &matcherFunc{
+		matches: func(x interface{}) bool {
+			for _, m := range matchers {
+				if m.Matches(x) {
+					return true
+				}
+			}
+			return false
+		},
+		str: joinMatchers("any of", matchers),
+	}
+}
+
+//	Not inverts m.
+func Not(m Matcher) Matcher {
+	return &matcherFunc{
+		matches: func(x interface{}) bool { return !m.Matches(x) },
+		str:     fmt.Sprintf("not(%s)", m.String()),
+	}
+}
+
+func joinMatchers(label string, matchers []Matcher) string {
+	strs := make([]string, len(matchers))
+	for i, m := range matchers {
+		strs[i] = m.String()
+	}
+	return fmt.Sprintf("%s %v", label, strs)
+}
@@ -0,0 +1,164 @@
+package stub
+
+import (
+	"fmt"
+	"reflect"
+)
+
+//	RegisterPromoted discovers every method promoted from the embedded
+//	field named embeddedFieldName on the stubbed object's type, so they
+//	can be used through Stub/Call/ValidateStubSignature by name, exactly
+//	as if they were direct methods of the stubbed object.
+//
+//	A method already in the stubbed object's own method set is silently
+//	skipped. That covers an outer method defined directly on the stubbed
+//	object, which wins over a promoted one of the same name (shadowing),
+//	and a method Go itself promotes unambiguously; Call and
+//	ValidateStubSignature already resolve both by name, so only names Go
+//	leaves out of the method set get registered. If embeddedFieldName's
+//	methods collide with those of a field registered in an earlier call,
+//	RegisterPromoted panics naming
+//	both paths rather than silently picking one - mirroring the ambiguous
+//	selector error the Go compiler would give for the equivalent
+//	unqualified reference.
+func (s *Mocked[SO]) RegisterPromoted(embeddedFieldName string) {
+	soType := reflect.TypeOf(s.stubbedObj).Elem()
+	field, found := soType.FieldByName(embeddedFieldName)
+	if !found || !field.Anonymous {
+		panic(fmt.Sprintf("%s is not an embedded field of %s", embeddedFieldName, soType.Name()))
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fieldType := field.Type
+	for i := 0; i < fieldType.NumMethod(); i++ {
+		m := fieldType.Method(i)
+		if _, shadowed := soType.MethodByName(m.Name); shadowed {
+			continue
+		}
+		if priorField, registered := s.promotedFieldByMethod[m.Name]; registered && priorField != embeddedFieldName {
+			panic(fmt.Sprintf("ambiguous promoted method %s: exposed by both %s and %s",
+				m.Name, priorField, embeddedFieldName))
+		}
+		if s.promotedFieldByMethod == nil {
+			s.promotedFieldByMethod = make(map[string]string)
+		}
+		s.promotedFieldByMethod[m.Name] = embeddedFieldName
+	}
+}
+
+//	lookupPromoted resolves methodName to a promoted method, but only if
+//	it was previously registered via RegisterPromoted. Call and
+//	ValidateStubSignature consult it only for a name missing from the
+//	stubbed object's method set; a method Go promotes unambiguously is
+//	found in that method set directly, without RegisterPromoted.
+func (s *Mocked[SO]) lookupPromoted(methodName string) (fieldName string, method reflect.Method, ok bool) {
+	s.mu.Lock()
+	_, registered := s.promotedFieldByMethod[methodName]
+	s.mu.Unlock()
+	if !registered {
+		return "", reflect.Method{}, false
+	}
+	return resolvePromotedMethod(reflect.TypeOf(s.stubbedObj).Elem(), methodName)
+}
+
+//	resolvePromotedMethod walks soType's embedded fields (recursively, to
+//	mirror how Go itself flattens multiple levels of embedding) looking
+//	for methodName. It mirrors the logic cmd/stubgen uses to flatten an
+//	interface's embedded method sets, but over struct fields instead. An
+//	ambiguous match - the same method name reachable through two different
+//	embedded fields - panics naming both paths.
+func resolvePromotedMethod(soType reflect.Type, methodName string) (fieldName string, method reflect.Method, ok bool) {
+	if soType.Kind() != reflect.Struct {
+		return "", reflect.Method{}, false
+	}
+
+	type match struct {
+		fieldName string
+		method    reflect.Method
+	}
+	var matches []match
+
+	for i := 0; i < soType.NumField(); i++ {
+		f := soType.Field(i)
+		if !f.Anonymous {
+			continue
+		}
+		if m, found := f.Type.MethodByName(methodName); found {
+			matches = append(matches, match{f.Name, m})
+			continue
+		}
+		elemType := f.Type
+		if elemType.Kind() == reflect.Ptr {
+			elemType = elemType.Elem()
+		}
+		if nestedField, m, found := resolvePromotedMethod(elemType, methodName); found {
+			matches = append(matches, match{fmt.Sprintf("%s.%s", f.Name, nestedField), m})
+		}
+	}
+
+	if len(matches) == 0 {
+		return "", reflect.Method{}, false
+	}
+	if len(matches) > 1 {
+		paths := make([]string, len(matches))
+		for i, m := range matches {
+			paths[i] = m.fieldName
+		}
+		panic(fmt.Sprintf("ambiguous promoted method %s: reachable via multiple embedded paths %v", methodName, paths))
+	}
+	return matches[0].fieldName, matches[0].method, true
+}
+
+//	promotedFieldValue returns the reflect.Value of the (possibly nested,
+//	dot-separated) field path produced by resolvePromotedMethod/
+//	RegisterPromoted, starting from the stubbed object.
+func promotedFieldValue(stubbedObj interface{}, fieldPath string) reflect.Value {
+	v := reflect.ValueOf(stubbedObj).Elem()
+	for _, name := range splitFieldPath(fieldPath) {
+		if v.Kind() == reflect.Ptr {
+			v = v.Elem()
+		}
+		v = v.FieldByName(name)
+	}
+	return v
+}
+
+//	receiverSubstitutedFuncType rebuilds promotedFuncType (whose In(0) is
+//	the embedded field's own type) with receiverType swapped in as the
+//	receiver, since Call always invokes a registered fn with the stubbed
+//	object itself as the receiver, regardless of which embedded field the
+//	method was promoted from.
+func receiverSubstitutedFuncType(receiverType reflect.Type, promotedFuncType reflect.Type) reflect.Type {
+	ins := make([]reflect.Type, promotedFuncType.NumIn())
+	ins[0] = receiverType
+	for i := 1; i < promotedFuncType.NumIn(); i++ {
+		ins[i] = promotedFuncType.In(i)
+	}
+	outs := make([]reflect.Type, promotedFuncType.NumOut())
+	for i := 0; i < promotedFuncType.NumOut(); i++ {
+		outs[i] = promotedFuncType.Out(i)
+	}
+	return reflect.FuncOf(ins, outs, promotedFuncType.IsVariadic())
+}
+
+func toInterfaceSlice(vals []reflect.Value) []interface{} {
+	out := make([]interface{}, len(vals))
+	for i, v := range vals {
+		out[i] = v.Interface()
+	}
+	return out
+}
+
+func splitFieldPath(path string) []string {
+	var parts []string
+	start := 0
+	for i := 0; i < len(path); i++ {
+		if path[i] == '.' {
+			parts = append(parts, path[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, path[start:])
+}
@@ -3,7 +3,11 @@ package stub
 import (
 	"fmt"
 	"reflect"
+	"strings"
 	"sync"
+	"time"
+
+	"github.com/CameronHonis/stub/match"
 )
 
 type MockedI interface {
@@ -17,8 +21,11 @@ type MockedI interface {
 	LastCallArgs(methodName string) []interface{}
 	MethodCallCount(methodName string) int
 	WasMethodCalledWith(methodName string, args ...interface{}) bool
+	WasMethodCalledMatching(methodName string, matchers ...match.Matcher) bool
+	CallsMatching(methodName string, matchers ...match.Matcher) [][]interface{}
 
 	Call(methodName string, args ...interface{}) []interface{}
+	CallAsync(methodName string, args ...interface{}) <-chan []interface{}
 }
 
 //	Mocked is a struct that provides concrete implementations
@@ -30,29 +37,56 @@ type MockedI interface {
 //		[where each struct embeds the next]
 //
 //	I realize that creating and formatting a wrapper to implement
-//	this struct may be a painful and delicate process, so I (plan on)
-//	providing a generator that will create the wrapper and the stubbed
-//	methods for you. The generator would be run as a separate step in
-//	the build process.
-//
-//	* See (TODO - insert file name of example) for an example of usage.
-//
-//	* See (TODO - insert file name of generated file) for the generated file.
-//
-//	* See (TODO - insert file name of generator here) for the generator source code.
+//	this struct may be a painful and delicate process, so cmd/stubgen
+//	generates the wrapper and the stubbed methods for you from a
+//	//go:generate directive; see cmd/stubgen for usage and the generated
+//	file format.
 type Mocked[SO any] struct {
-	wrapper              interface{} // the struct that wraps the stubbed object
-	stubbedObj           *SO         // the struct being stubbed
-	fnByMethodName       map[string]interface{}
-	callArgsByMethodName map[string][][]interface{}
-	mu                   sync.Mutex // just in case tests run in parallel (is this overkill?)
+	wrapper               interface{} // the struct that wraps the stubbed object
+	stubbedObj            *SO         // the struct being stubbed
+	fnByMethodName        map[string]*stubEntry
+	callArgsByMethodName  map[string][][]interface{}
+	callHook              CallHook
+	promotedFieldByMethod map[string]string // methodName -> embedded field name/path it was promoted from, via RegisterPromoted
+	mu                    sync.Mutex        // just in case tests run in parallel (is this overkill?)
+}
+
+//	stubEntry is what's registered per methodName: the fn to invoke, plus
+//	the optional async behavior installed by StubAsync/StubAfter that Call
+//	waits on before invoking fn.
+type stubEntry struct {
+	fn      interface{}
+	waitFor <-chan time.Time
+	delay   time.Duration
+}
+
+//	CallHook lets something external (namely a Controller) intercept a
+//	Mocked's calls before they fall through to the registered stub fn (or
+//	the real stubbed method). handled reports whether hook has fully
+//	resolved the call; if false, rets is ignored and Call proceeds as if
+//	no hook were installed.
+type CallHook func(methodName string, args []interface{}) (rets []interface{}, handled bool)
+
+//	CallHookable is satisfied by any *Mocked[SO], regardless of SO. A
+//	Controller depends only on this interface so it can manage
+//	expectations across Mocked instances stubbing unrelated types.
+type CallHookable interface {
+	SetCallHook(hook CallHook)
+}
+
+//	SetCallHook installs (or clears, with nil) the CallHook consulted at
+//	the top of Call.
+func (s *Mocked[SO]) SetCallHook(hook CallHook) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.callHook = hook
 }
 
 func NewMocked[SO any](wrapper interface{}, objToStub *SO) *Mocked[SO] {
 	return &Mocked[SO]{
 		wrapper:              wrapper,
 		stubbedObj:           objToStub,
-		fnByMethodName:       make(map[string]interface{}),
+		fnByMethodName:       make(map[string]*stubEntry),
 		callArgsByMethodName: make(map[string][][]interface{}),
 	}
 }
@@ -65,8 +99,27 @@ func NewMocked[SO any](wrapper interface{}, objToStub *SO) *Mocked[SO] {
 func (s *Mocked[SO]) Stub(methodName string, fn interface{}) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
-	ValidateStubSignature(s.stubbedObj, methodName, fn)
-	s.fnByMethodName[methodName] = fn
+	ValidateStubSignature(s.stubbedObj, methodName, fn, s.promotedFieldByMethod)
+	s.fnByMethodName[methodName] = &stubEntry{fn: fn}
+}
+
+//	StubAsync is like Stub, but delays invoking fn until waitFor fires.
+//	This lets a test drive a stubbed method's completion from another
+//	goroutine, useful for exercising timeout/deadline/cancellation paths
+//	that a synchronous stub can't reach.
+func (s *Mocked[SO]) StubAsync(methodName string, waitFor <-chan time.Time, fn interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ValidateStubSignature(s.stubbedObj, methodName, fn, s.promotedFieldByMethod)
+	s.fnByMethodName[methodName] = &stubEntry{fn: fn, waitFor: waitFor}
+}
+
+//	StubAfter is like Stub, but sleeps for delay before invoking fn.
+func (s *Mocked[SO]) StubAfter(methodName string, delay time.Duration, fn interface{}) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ValidateStubSignature(s.stubbedObj, methodName, fn, s.promotedFieldByMethod)
+	s.fnByMethodName[methodName] = &stubEntry{fn: fn, delay: delay}
 }
 
 func (s *Mocked[SO]) IsStubbed(methodName string) bool {
@@ -116,22 +169,91 @@ func (s *Mocked[SO]) MethodCallCount(methodName string) int {
 	return len(s.callArgsByMethodName[methodName])
 }
 
+//	WasMethodCalledWith reports whether methodName was ever called with
+//	args. Any element of args that is a match.Matcher is compared against
+//	the corresponding call arg via Matches instead of reflect.DeepEqual,
+//	so callers can mix exact values and matchers freely, e.g.
+//	WasMethodCalledWith("Foo", match.Any(), "bar").
 func (s *Mocked[SO]) WasMethodCalledWith(methodName string, args ...interface{}) bool {
-	s.mu.Lock()
-	defer s.mu.Unlock()
 	for _, callArgs := range s.AllCallArgs(methodName) {
-		if reflect.DeepEqual(callArgs, args) {
+		if argsMatch(callArgs, args) {
 			return true
 		}
 	}
 	return false
 }
 
+//	WasMethodCalledMatching reports whether methodName was ever called
+//	with args that match every one of matchers, positionally.
+func (s *Mocked[SO]) WasMethodCalledMatching(methodName string, matchers ...match.Matcher) bool {
+	args := make([]interface{}, len(matchers))
+	for i, m := range matchers {
+		args[i] = m
+	}
+	return s.WasMethodCalledWith(methodName, args...)
+}
+
+//	CallsMatching returns every recorded call to methodName whose args
+//	match every one of matchers, positionally.
+func (s *Mocked[SO]) CallsMatching(methodName string, matchers ...match.Matcher) [][]interface{} {
+	args := make([]interface{}, len(matchers))
+	for i, m := range matchers {
+		args[i] = m
+	}
+	matching := make([][]interface{}, 0)
+	for _, callArgs := range s.AllCallArgs(methodName) {
+		if argsMatch(callArgs, args) {
+			matching = append(matching, callArgs)
+		}
+	}
+	return matching
+}
+
+//	argsMatch compares recorded callArgs against want positionally: any
+//	element of want that is a match.Matcher is compared via Matches,
+//	everything else via reflect.DeepEqual.
+func argsMatch(callArgs, want []interface{}) bool {
+	if len(callArgs) != len(want) {
+		return false
+	}
+	for i, w := range want {
+		if m, ok := w.(match.Matcher); ok {
+			if !m.Matches(callArgs[i]) {
+				return false
+			}
+			continue
+		}
+		if !reflect.DeepEqual(callArgs[i], w) {
+			return false
+		}
+	}
+	return true
+}
+
 func (s *Mocked[SO]) Call(methodName string, args ...interface{}) []interface{} {
 	s.mu.Lock()
-	fn := s.fnByMethodName[methodName]
+	entry := s.fnByMethodName[methodName]
+	hook := s.callHook
 	s.mu.Unlock()
 
+	if hook != nil {
+		if rets, handled := hook(methodName, args); handled {
+			s.addCallArgs(methodName, args...)
+			return s.padRets(methodName, rets)
+		}
+	}
+
+	var fn interface{}
+	if entry != nil {
+		fn = entry.fn
+		if entry.waitFor != nil {
+			<-entry.waitFor
+		}
+		if entry.delay > 0 {
+			time.Sleep(entry.delay)
+		}
+	}
+
 	inVals := make([]reflect.Value, len(args)+1)
 	inVals[0] = reflect.ValueOf(s.stubbedObj)
 	for i, arg := range args {
@@ -140,10 +262,16 @@ func (s *Mocked[SO]) Call(methodName string, args ...interface{}) []interface{}
 
 	if fn == nil {
 		sMethod, methodFound := reflect.TypeOf(s.stubbedObj).MethodByName(methodName)
-		if !methodFound {
+		if methodFound {
+			fn = sMethod.Func.Interface()
+		} else if fieldName, promotedMethod, found := s.lookupPromoted(methodName); found {
+			fieldVal := promotedFieldValue(s.stubbedObj, fieldName)
+			out := toInterfaceSlice(promotedMethod.Func.Call(append([]reflect.Value{fieldVal}, inVals[1:]...)))
+			s.addCallArgs(methodName, args...)
+			return out
+		} else {
 			panic(fmt.Sprintf("stubbed object does not have a method named %s", methodName))
 		}
-		fn = sMethod.Func.Interface()
 	}
 	fnVal := reflect.ValueOf(fn)
 	outVals := fnVal.Call(inVals)
@@ -155,6 +283,48 @@ func (s *Mocked[SO]) Call(methodName string, args ...interface{}) []interface{}
 	return out
 }
 
+//	padRets pads or truncates rets to methodName's actual result count, so
+//	a CallHook (namely Controller's dispatch) that returns the wrong
+//	number of values - e.g. an Expectation whose Return was never called,
+//	or was called with the wrong arity - fails the generated wrapper's
+//	stub.RetOrZero[T](rets[i]) as a zero value rather than an
+//	index-out-of-range panic. methodName not being resolvable is left
+//	alone; Call's normal (non-hook) path will panic with a clearer error.
+func (s *Mocked[SO]) padRets(methodName string, rets []interface{}) []interface{} {
+	n, ok := s.resultCount(methodName)
+	if !ok || len(rets) == n {
+		return rets
+	}
+	out := make([]interface{}, n)
+	copy(out, rets)
+	return out
+}
+
+//	resultCount returns the number of results methodName declares on the
+//	stubbed object, checking promoted methods too. ok is false if
+//	methodName isn't a method of the stubbed object at all.
+func (s *Mocked[SO]) resultCount(methodName string) (n int, ok bool) {
+	if m, found := reflect.TypeOf(s.stubbedObj).MethodByName(methodName); found {
+		return m.Type.NumOut(), true
+	}
+	if _, m, found := s.lookupPromoted(methodName); found {
+		return m.Type.NumOut(), true
+	}
+	return 0, false
+}
+
+//	CallAsync dispatches Call on a new goroutine and returns a channel
+//	that receives its result once the call completes. Use this to invoke a
+//	stub registered with StubAsync/StubAfter without blocking the calling
+//	goroutine on it.
+func (s *Mocked[SO]) CallAsync(methodName string, args ...interface{}) <-chan []interface{} {
+	out := make(chan []interface{}, 1)
+	go func() {
+		out <- s.Call(methodName, args...)
+	}()
+	return out
+}
+
 func (s *Mocked[SO]) addCallArgs(methodName string, args ...interface{}) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -164,7 +334,24 @@ func (s *Mocked[SO]) addCallArgs(methodName string, args ...interface{}) {
 	s.callArgsByMethodName[methodName] = append(s.callArgsByMethodName[methodName], args)
 }
 
-func ValidateStubSignature(stubbedObject interface{}, methodName string, fn interface{}) {
+//	RetOrZero type-asserts v to T, falling back to T's zero value when v
+//	is nil. This is what generated StubWrapper methods use to unpack the
+//	[]interface{} returned by Call: a plain `v.(T)` assertion panics on a
+//	nil interface instead of yielding the zero value the caller expects.
+func RetOrZero[T any](v interface{}) T {
+	var zero T
+	if v == nil {
+		return zero
+	}
+	return v.(T)
+}
+
+//	registeredPromoted is the stubbing Mocked's promotedFieldByMethod (see
+//	RegisterPromoted). It is consulted only when methodName is not in the
+//	stubbed object's method set, which already includes methods Go
+//	promotes unambiguously from embedded fields; methodName then resolves
+//	to a promoted method only if it's a key of this map.
+func ValidateStubSignature(stubbedObject interface{}, methodName string, fn interface{}, registeredPromoted map[string]string) {
 	fnVal := reflect.ValueOf(fn)
 	if fnVal.Kind() != reflect.Func {
 		panic("fn must be a function")
@@ -177,40 +364,103 @@ func ValidateStubSignature(stubbedObject interface{}, methodName string, fn inte
 		soName = soType.Elem().Name()
 	}
 
-	soValMethod, foundMethod := soType.MethodByName(methodName)
-	if !foundMethod {
-		fmt.Println("method count: ", soType.NumMethod())
+	var soFuncType reflect.Type
+	if soValMethod, foundMethod := soType.MethodByName(methodName); foundMethod {
+		soFuncType = soValMethod.Func.Type()
+	} else if _, registered := registeredPromoted[methodName]; registered {
+		_, promotedMethod, foundPromoted := resolvePromotedMethod(soType.Elem(), methodName)
+		if !foundPromoted {
+			panic(fmt.Sprintf("methodName (%s) must be a method of %s", methodName, soName))
+		}
+		// promotedMethod's receiver is the embedded field's type, not
+		// stubbedObject's; synthesize the signature fn is actually
+		// validated against - and invoked with, via Call - which always
+		// passes stubbedObject itself as the receiver.
+		soFuncType = receiverSubstitutedFuncType(soType, promotedMethod.Func.Type())
+	} else {
 		panic(fmt.Sprintf("methodName (%s) must be a method of %s", methodName, soName))
 	}
 
 	// assert i/o count matches
 	fnType := fnVal.Type()
-	soFuncType := soValMethod.Func.Type()
 	fnNumIn := fnType.NumIn()
 	soFuncNumIn := soFuncType.NumIn()
 
 	if soFuncNumIn != fnNumIn {
 		//	NOTE: this compares fn to the "under the hood" GENERATED function based upon the method signature
 		//	this adds the receiver as the first argument
-		panic(fmt.Sprintf("fn must have the same arg count as %s.%s's func signature\nDid you forget to include the receiver arg?", soName, methodName))
+		msg := fmt.Sprintf("fn must have the same arg count as %s.%s's func signature", soName, methodName)
+		if forgotReceiver(fnType, soFuncType) {
+			msg += "\nDid you forget to include the receiver arg?"
+		}
+		panic(msg + "\n" + suggestedStubSignature(soName, methodName, soFuncType))
 	}
 	fnNumOut := fnType.NumOut()
 	soFuncNumOut := soFuncType.NumOut()
 	if soFuncNumOut != fnNumOut {
-		panic(fmt.Sprintf("fn must have the same return count as %s.%s's func signature", soName, methodName))
+		panic(fmt.Sprintf("fn must have the same return count as %s.%s's func signature\n%s",
+			soName, methodName, suggestedStubSignature(soName, methodName, soFuncType)))
 	}
 
 	// assert each i/o type match
 	for i := 0; i < fnNumIn; i++ {
 		if soFuncType.In(i) != fnType.In(i) {
 			panic(fmt.Sprintf("fn param #%d must have the same type as %s.%s's func signature:"+
-				"\n\t%s (expected) is not %s (actual)", i, soName, methodName, soFuncType.In(i), fnType.In(i)))
+				"\n\t%s (expected) is not %s (actual)\n%s",
+				i, soName, methodName, soFuncType.In(i), fnType.In(i), suggestedStubSignature(soName, methodName, soFuncType)))
 		}
 	}
 	for i := 0; i < fnNumOut; i++ {
 		if soFuncType.Out(i) != fnType.Out(i) {
 			panic(fmt.Sprintf("fn return #%d must have the same type as %s.%s's func signature:"+
-				"\n\t%s (expected) is not %s (actual)", i, soName, methodName, soFuncType.Out(i), fnType.In(i)))
+				"\n\t%s (expected) is not %s (actual)\n%s",
+				i, soName, methodName, soFuncType.Out(i), fnType.Out(i), suggestedStubSignature(soName, methodName, soFuncType)))
+		}
+	}
+}
+
+//	forgotReceiver reports whether fnType looks like soFuncType with the
+//	receiver arg simply left off - the single most common way
+//	ValidateStubSignature fails: fnType has exactly one fewer arg than
+//	soFuncType, and every arg fnType does have lines up with soFuncType's
+//	args shifted by one.
+func forgotReceiver(fnType, soFuncType reflect.Type) bool {
+	if soFuncType.NumIn()-fnType.NumIn() != 1 {
+		return false
+	}
+	for i := 0; i < fnType.NumIn(); i++ {
+		if fnType.In(i) != soFuncType.In(i+1) {
+			return false
+		}
+	}
+	return true
+}
+
+//	suggestedStubSignature renders a header line naming soName.methodName
+//	followed by a ready-to-paste stub func literal for soFuncType (the
+//	stubbed method's own, receiver-included, func type), e.g.
+//	"Expected signature for Foo.Bar:\n\tfunc(s *pkg.Foo, arg0 int, arg1 string) (bool, error) { ... }".
+func suggestedStubSignature(soName, methodName string, soFuncType reflect.Type) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Expected signature for %s.%s:\n\tfunc(s %s", soName, methodName, soFuncType.In(0))
+	for i := 1; i < soFuncType.NumIn(); i++ {
+		fmt.Fprintf(&b, ", arg%d %s", i-1, soFuncType.In(i))
+	}
+	b.WriteString(")")
+	switch soFuncType.NumOut() {
+	case 0:
+	case 1:
+		fmt.Fprintf(&b, " %s", soFuncType.Out(0))
+	default:
+		b.WriteString(" (")
+		for i := 0; i < soFuncType.NumOut(); i++ {
+			if i > 0 {
+				b.WriteString(", ")
+			}
+			fmt.Fprintf(&b, "%s", soFuncType.Out(i))
 		}
+		b.WriteString(")")
 	}
+	b.WriteString(" { ... }")
+	return b.String()
 }
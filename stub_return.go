@@ -0,0 +1,117 @@
+package stub
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+//	StubReturn installs a synthetic stub for methodName that ignores its
+//	arguments and always returns rets. It's built via reflect.MakeFunc from
+//	the stubbed method's own signature, so rets is type-checked against
+//	each output type at registration time instead of panicking later, deep
+//	inside a test, when the stub is finally called.
+func (s *Mocked[SO]) StubReturn(methodName string, rets ...interface{}) {
+	funcType := s.stubbedMethodFuncType(methodName)
+	outVals := retsToValues(methodName, funcType, rets)
+	fn := reflect.MakeFunc(funcType, func(_ []reflect.Value) []reflect.Value {
+		return outVals
+	})
+	s.Stub(methodName, fn.Interface())
+}
+
+//	StubReturnOnce is like StubReturn, but only answers the next call to
+//	methodName. Once consumed, subsequent calls fall through to the real
+//	stubbed method (or panic, if the stubbed object has no such method).
+func (s *Mocked[SO]) StubReturnOnce(methodName string, rets ...interface{}) {
+	s.StubReturnSequence(methodName, [][]interface{}{rets})
+}
+
+//	StubReturnSequence answers each successive call to methodName with the
+//	next entry of retsSequence. Once every entry has been consumed,
+//	subsequent calls fall through to the real stubbed method (or panic, if
+//	the stubbed object has no such method). Every entry is type-checked
+//	against methodName's signature up front, at registration time.
+func (s *Mocked[SO]) StubReturnSequence(methodName string, retsSequence [][]interface{}) {
+	funcType := s.stubbedMethodFuncType(methodName)
+	queue := make([][]reflect.Value, len(retsSequence))
+	for i, rets := range retsSequence {
+		queue[i] = retsToValues(methodName, funcType, rets)
+	}
+
+	var mu sync.Mutex
+	idx := 0
+	fn := reflect.MakeFunc(funcType, func(in []reflect.Value) []reflect.Value {
+		mu.Lock()
+		defer mu.Unlock()
+		if idx >= len(queue) {
+			return s.callRealOrPanic(methodName, in)
+		}
+		outVals := queue[idx]
+		idx++
+		return outVals
+	})
+	s.Stub(methodName, fn.Interface())
+}
+
+//	StubReturnFromChan answers each successive call to methodName with the
+//	next value received on ch, blocking the caller until one arrives. Once
+//	ch is closed, subsequent calls fall through to the real stubbed method
+//	(or panic, if the stubbed object has no such method). This is useful
+//	for driving stubbed methods from a test goroutine in lockstep with the
+//	code under test.
+func (s *Mocked[SO]) StubReturnFromChan(methodName string, ch <-chan []interface{}) {
+	funcType := s.stubbedMethodFuncType(methodName)
+	fn := reflect.MakeFunc(funcType, func(in []reflect.Value) []reflect.Value {
+		rets, ok := <-ch
+		if !ok {
+			return s.callRealOrPanic(methodName, in)
+		}
+		return retsToValues(methodName, funcType, rets)
+	})
+	s.Stub(methodName, fn.Interface())
+}
+
+//	stubbedMethodFuncType returns the full func type (receiver included) of
+//	methodName on the stubbed object.
+func (s *Mocked[SO]) stubbedMethodFuncType(methodName string) reflect.Type {
+	sMethod, found := reflect.TypeOf(s.stubbedObj).MethodByName(methodName)
+	if !found {
+		panic(fmt.Sprintf("stubbed object does not have a method named %s", methodName))
+	}
+	return sMethod.Func.Type()
+}
+
+//	callRealOrPanic is the shared "ran out of canned returns" fallback for
+//	StubReturnSequence/StubReturnFromChan: call through to the real stubbed
+//	method if one exists, otherwise panic.
+func (s *Mocked[SO]) callRealOrPanic(methodName string, in []reflect.Value) []reflect.Value {
+	sMethod, found := reflect.TypeOf(s.stubbedObj).MethodByName(methodName)
+	if !found {
+		panic(fmt.Sprintf("%s has no more canned returns and stubbed object has no real method to fall back to", methodName))
+	}
+	return sMethod.Func.Call(in)
+}
+
+//	retsToValues type-checks rets against funcType's outputs and converts
+//	them to the reflect.Values MakeFunc expects, substituting the
+//	appropriate zero value for a nil entry (e.g. a nil error).
+func retsToValues(methodName string, funcType reflect.Type, rets []interface{}) []reflect.Value {
+	if len(rets) != funcType.NumOut() {
+		panic(fmt.Sprintf("%s returns %d value(s), got %d", methodName, funcType.NumOut(), len(rets)))
+	}
+	outVals := make([]reflect.Value, len(rets))
+	for i, ret := range rets {
+		outType := funcType.Out(i)
+		if ret == nil {
+			outVals[i] = reflect.Zero(outType)
+			continue
+		}
+		retVal := reflect.ValueOf(ret)
+		if !retVal.Type().AssignableTo(outType) {
+			panic(fmt.Sprintf("%s return #%d must be assignable to %s, got %s", methodName, i, outType, retVal.Type()))
+		}
+		outVals[i] = retVal
+	}
+	return outVals
+}